@@ -0,0 +1,312 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty"
+	"github.com/sirupsen/logrus"
+)
+
+// PasswordList is the PasswordState JSON response for a password entry.
+type PasswordList struct {
+	PasswordID     int    `json:"PasswordID"`
+	Title          string `json:"Title"`
+	UserName       string `json:"UserName"`
+	Description    string `json:"Description"`
+	GenericField1  string `json:"GenericField1"`
+	GenericField2  string `json:"GenericField2"`
+	GenericField3  string `json:"GenericField3"`
+	GenericField4  string `json:"GenericField4"`
+	GenericField5  string `json:"GenericField5"`
+	GenericField6  string `json:"GenericField6"`
+	GenericField7  string `json:"GenericField7"`
+	GenericField8  string `json:"GenericField8"`
+	GenericField9  string `json:"GenericField9"`
+	GenericField10 string `json:"GenericField10"`
+	AccountTypeID  int    `json:"AccountTypeID"`
+	Notes          string `json:"Notes"`
+	URL            string `json:"URL"`
+	Password       string `json:"Password"`
+	ExpiryDate     string `json:"ExpiryDate"`
+	AllowExport    bool   `json:"AllowExport"`
+	AccountType    string `json:"AccountType"`
+}
+
+// sourcedSecret is a Secret together with the ID of the password list or
+// password it was fetched from, used to disambiguate duplicates.
+type sourcedSecret struct {
+	secret Secret
+	listID int
+}
+
+// newPasswordStateClient builds the resty client shared by every fetcher,
+// applying the connection, debug, and TLS settings from the configuration.
+func newPasswordStateClient(p *Plugin) (*resty.Client, error) {
+	maxWait := time.Duration(p.Config.RetryMaxWait) * time.Second
+	if maxWait == 0 {
+		maxWait = 30 * time.Second
+	}
+
+	client := resty.New()
+	client.
+		SetRetryCount(p.Config.ConnectionRetries).
+		SetRetryWaitTime(defaultRetryWait).
+		SetRetryMaxWaitTime(maxWait).
+		AddRetryCondition(shouldRetry(newAttemptCounter())).
+		SetTimeout(time.Duration(p.Config.ConnectionTimeout) * time.Second)
+	if p.Config.Debug {
+		client.SetDebug(true)
+	}
+	if p.Config.SkipTlsVerify || p.Config.ClientCertFile != "" || p.Config.CaCertFile != "" {
+		tlsConfig, err := buildTLSConfig(p)
+		if err != nil {
+			return nil, err
+		}
+		client.SetTLSClientConfig(tlsConfig)
+	}
+	// Wrap whatever transport resty has set up (including the TLS config just
+	// applied above) so a Retry-After header is honored before resty's own
+	// backoff runs for the next attempt. resty only lazily initializes its
+	// own *http.Transport, so fall back to the default one if none is set yet.
+	transport := client.GetClient().Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client.SetTransport(&retryAfterTransport{next: transport, jitter: p.Config.RetryBackoffJitter})
+	return client, nil
+}
+
+// fetchPasswordList retrieves every password in the given password list.
+func fetchPasswordList(client *resty.Client, p *Plugin, listID int) ([]PasswordList, error) {
+	var url strings.Builder
+	url.WriteString(strings.TrimRight(p.Config.ApiEndpoint, "/"))
+	url.WriteString("/passwords/{PasswordListID}")
+
+	logrus.WithField("endpoint", p.Config.ApiEndpoint).WithField("list_id", listID).Infoln("Querying PasswordState API for a password list.")
+	response, err := client.R().
+		SetResult([]PasswordList{}).
+		SetQueryParams(map[string]string{
+			"QueryAll":        "true",
+			"PreventAuditing": "false",
+		}).
+		SetPathParams(map[string]string{
+			"PasswordListID": strconv.Itoa(listID),
+		}).
+		SetHeaders(map[string]string{
+			"APIKey":       p.Config.ApiKey,
+			"Content-Type": "application/json",
+		}).
+		Get(url.String())
+
+	if err != nil {
+		logrus.WithError(err).WithField("list_id", listID).Errorln("Failed to retrieve the password list from PasswordState.")
+		return nil, err
+	}
+	if response.IsError() {
+		logrus.WithField("list_id", listID).WithField("status", response.StatusCode()).Errorln("PasswordState returned an error for the password list.")
+		return nil, fmt.Errorf("passwordstate returned status %d for password list %d", response.StatusCode(), listID)
+	}
+
+	passwords := *response.Result().(*[]PasswordList)
+	logrus.WithField("list_id", listID).WithField("count", len(passwords)).Infoln("Passwords retrieved from the password list.")
+	return passwords, nil
+}
+
+// fetchPassword retrieves a single password by its ID.
+func fetchPassword(client *resty.Client, p *Plugin, passwordID int) (PasswordList, error) {
+	var url strings.Builder
+	url.WriteString(strings.TrimRight(p.Config.ApiEndpoint, "/"))
+	url.WriteString("/passwords/{PasswordID}")
+
+	logrus.WithField("endpoint", p.Config.ApiEndpoint).WithField("password_id", passwordID).Infoln("Querying PasswordState API for a single password.")
+	response, err := client.R().
+		SetResult([]PasswordList{}).
+		SetQueryParams(map[string]string{
+			"QueryAll": "false",
+		}).
+		SetPathParams(map[string]string{
+			"PasswordID": strconv.Itoa(passwordID),
+		}).
+		SetHeaders(map[string]string{
+			"APIKey":       p.Config.ApiKey,
+			"Content-Type": "application/json",
+		}).
+		Get(url.String())
+
+	if err != nil {
+		logrus.WithError(err).WithField("password_id", passwordID).Errorln("Failed to retrieve the password from PasswordState.")
+		return PasswordList{}, err
+	}
+	if response.IsError() {
+		logrus.WithField("password_id", passwordID).WithField("status", response.StatusCode()).Errorln("PasswordState returned an error for the password.")
+		return PasswordList{}, fmt.Errorf("passwordstate returned status %d for password %d", response.StatusCode(), passwordID)
+	}
+
+	passwords := *response.Result().(*[]PasswordList)
+	if len(passwords) == 0 {
+		return PasswordList{}, fmt.Errorf("passwordstate returned no password for id %d", passwordID)
+	}
+	return passwords[0], nil
+}
+
+// passwordToSecret extracts the configured key/value fields from a password,
+// skipping (with a warning) entries where either field is blank, then runs
+// the key/value transform pipeline (see transform.go).
+func passwordToSecret(p *Plugin, password PasswordList) (Secret, bool) {
+	key := reflect.Indirect(reflect.ValueOf(password)).FieldByName(p.Config.KeyField).String()
+	if key == "" || key == "<invalid Value>" {
+		logrus.WithField("password_id", password.PasswordID).WithField("field", p.Config.KeyField).Warnln("Key is empty. Skipping the secret.")
+		return Secret{}, false
+	}
+	value := reflect.Indirect(reflect.ValueOf(password)).FieldByName(p.Config.ValueField).String()
+	if value == "" || value == "<invalid Value>" {
+		logrus.WithField("password_id", password.PasswordID).WithField("field", p.Config.ValueField).Warnln("Value is empty. Skipping the secret.")
+		return Secret{}, false
+	}
+
+	key, keep, err := applyKeyTransforms(p, password, key)
+	if err != nil {
+		logrus.WithError(err).WithField("password_id", password.PasswordID).Errorln("Failed to transform the secret key.")
+		return Secret{}, false
+	}
+	if !keep {
+		logrus.WithField("password_id", password.PasswordID).Debugln("Key filtered out by KeyRegex. Skipping the secret.")
+		return Secret{}, false
+	}
+
+	value, keep, err = applyValueTransforms(p, password, value)
+	if err != nil {
+		logrus.WithError(err).WithField("password_id", password.PasswordID).Errorln("Failed to transform the secret value.")
+		return Secret{}, false
+	}
+	if !keep {
+		logrus.WithField("password_id", password.PasswordID).Debugln("Value filtered out by ValueRegex. Skipping the secret.")
+		return Secret{}, false
+	}
+
+	return Secret{Key: key, Value: value}, true
+}
+
+// mergeSecrets de-duplicates secrets collected from multiple sources on their
+// Key, honoring the configured OnDuplicate policy. Collisions are determined
+// up front across the whole set, so which entries get a policy applied to
+// them never depends on fetch order.
+func mergeSecrets(policy string, items []sourcedSecret) ([]Secret, error) {
+	if policy == "" {
+		policy = "fail"
+	}
+
+	switch policy {
+	case "fail", "first", "last", "prefix-with-listid":
+	default:
+		return nil, fmt.Errorf("unsupported on_duplicate policy: %s", policy)
+	}
+
+	counts := make(map[string]int, len(items))
+	for _, item := range items {
+		counts[item.secret.Key]++
+	}
+
+	switch policy {
+	case "fail":
+		for key, count := range counts {
+			if count > 1 {
+				return nil, fmt.Errorf("duplicate secret key %q, set OnDuplicate to resolve it", key)
+			}
+		}
+		result := make([]Secret, len(items))
+		for i, item := range items {
+			result[i] = item.secret
+		}
+		return result, nil
+
+	case "first":
+		seen := make(map[string]bool, len(items))
+		var result []Secret
+		for _, item := range items {
+			if seen[item.secret.Key] {
+				logrus.WithField("key", item.secret.Key).WithField("on_duplicate", policy).Warnln("Duplicate secret key encountered.")
+				continue
+			}
+			seen[item.secret.Key] = true
+			result = append(result, item.secret)
+		}
+		return result, nil
+
+	case "last":
+		index := make(map[string]int, len(items))
+		var result []Secret
+		for _, item := range items {
+			if idx, ok := index[item.secret.Key]; ok {
+				logrus.WithField("key", item.secret.Key).WithField("on_duplicate", policy).Warnln("Duplicate secret key encountered.")
+				result[idx] = item.secret
+				continue
+			}
+			index[item.secret.Key] = len(result)
+			result = append(result, item.secret)
+		}
+		return result, nil
+
+	default: // "prefix-with-listid"
+		result := make([]Secret, 0, len(items))
+		for _, item := range items {
+			if counts[item.secret.Key] == 1 {
+				result = append(result, item.secret)
+				continue
+			}
+			logrus.WithField("key", item.secret.Key).WithField("on_duplicate", policy).Warnln("Duplicate secret key encountered.")
+			result = append(result, Secret{
+				Key:   fmt.Sprintf("%d_%s", item.listID, item.secret.Key),
+				Value: item.secret.Value,
+			})
+		}
+		return result, nil
+	}
+}
+
+// getSecrets fetches every configured password list and password from
+// PasswordState and merges the results into a single, de-duplicated slice.
+func getSecrets(p *Plugin) ([]Secret, error) {
+	client, err := newPasswordStateClient(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []sourcedSecret
+	for _, listID := range p.Config.PasswordListIds {
+		passwords, err := fetchPasswordList(client, p, listID)
+		if err != nil {
+			return nil, err
+		}
+		for _, password := range passwords {
+			if secret, ok := passwordToSecret(p, password); ok {
+				items = append(items, sourcedSecret{secret: secret, listID: listID})
+			}
+		}
+	}
+
+	for _, passwordID := range p.Config.PasswordIds {
+		password, err := fetchPassword(client, p, passwordID)
+		if err != nil {
+			return nil, err
+		}
+		if secret, ok := passwordToSecret(p, password); ok {
+			items = append(items, sourcedSecret{secret: secret, listID: passwordID})
+		}
+	}
+
+	logrus.WithField("key_field", p.Config.KeyField).WithField("value_field", p.Config.ValueField).WithField("count", len(items)).Infoln("Merging secrets retrieved from all sources.")
+	secrets, err := mergeSecrets(p.Config.OnDuplicate, items)
+	if err != nil {
+		logrus.WithError(err).Errorln("Failed to merge secrets from all sources.")
+		return nil, err
+	}
+
+	logrus.WithField("count", len(secrets)).Infoln("Finished processing the secrets.")
+	return secrets, nil
+}
@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSecretsDuplicatePolicies(t *testing.T) {
+	items := []sourcedSecret{
+		{secret: Secret{Key: "username", Value: "first"}, listID: 1},
+		{secret: Secret{Key: "username", Value: "second"}, listID: 2},
+		{secret: Secret{Key: "password", Value: "unique"}, listID: 1},
+	}
+
+	cases := []struct {
+		name    string
+		policy  string
+		want    []Secret
+		wantErr bool
+	}{
+		{
+			name:    "fail",
+			policy:  "fail",
+			wantErr: true,
+		},
+		{
+			name:   "first",
+			policy: "first",
+			want: []Secret{
+				{Key: "username", Value: "first"},
+				{Key: "password", Value: "unique"},
+			},
+		},
+		{
+			name:   "last",
+			policy: "last",
+			want: []Secret{
+				{Key: "username", Value: "second"},
+				{Key: "password", Value: "unique"},
+			},
+		},
+		{
+			name:   "prefix-with-listid",
+			policy: "prefix-with-listid",
+			want: []Secret{
+				{Key: "1_username", Value: "first"},
+				{Key: "2_username", Value: "second"},
+				{Key: "password", Value: "unique"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := mergeSecrets(c.policy, items)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("mergeSecrets(%q) = %v, want %v", c.policy, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMergeSecretsNoDuplicates(t *testing.T) {
+	items := []sourcedSecret{
+		{secret: Secret{Key: "username", Value: "admin"}, listID: 1},
+		{secret: Secret{Key: "password", Value: "secret"}, listID: 1},
+	}
+
+	got, err := mergeSecrets("fail", items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Secret{
+		{Key: "username", Value: "admin"},
+		{Key: "password", Value: "secret"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergeSecrets() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeSecretsUnsupportedPolicy(t *testing.T) {
+	items := []sourcedSecret{{secret: Secret{Key: "k", Value: "v"}, listID: 1}}
+	if _, err := mergeSecrets("bogus", items); err == nil {
+		t.Fatal("expected an error for an unsupported OnDuplicate policy")
+	}
+}
@@ -0,0 +1,224 @@
+package plugin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+)
+
+type (
+	// Outputter writes the retrieved secrets to a destination in a specific format.
+	Outputter interface {
+		Write(path string, section string, secrets []Secret) error
+	}
+
+	yamlOutputter struct {
+		encode bool
+	}
+
+	jsonOutputter struct {
+		encode bool
+	}
+
+	envOutputter struct {
+		encode bool
+	}
+
+	hclOutputter struct {
+		encode bool
+	}
+
+	templateOutputter struct {
+		templatePath string
+		encode       bool
+	}
+
+	// templateSecret is the shape exposed to a user-provided Go template.
+	templateSecret struct {
+		Key   string
+		Value string
+	}
+)
+
+// outputterRegistry maps a Config.OutputFormat value to the constructor of its Outputter.
+// New formats are added here rather than via a growing switch statement.
+var outputterRegistry = map[string]func(p *Plugin) Outputter{
+	"YAML": func(p *Plugin) Outputter {
+		return &yamlOutputter{encode: p.Config.EncodeSecrets}
+	},
+	"JSON": func(p *Plugin) Outputter {
+		return &jsonOutputter{encode: p.Config.EncodeSecrets}
+	},
+	"ENV": func(p *Plugin) Outputter {
+		return &envOutputter{encode: p.Config.EncodeSecrets}
+	},
+	"HCL": func(p *Plugin) Outputter {
+		return &hclOutputter{encode: p.Config.EncodeSecrets}
+	},
+	"TEMPLATE": func(p *Plugin) Outputter {
+		return &templateOutputter{templatePath: p.Config.Template, encode: p.Config.EncodeSecrets}
+	},
+}
+
+// newOutputter resolves the Outputter registered for the given format, if any.
+func newOutputter(p *Plugin, format string) (Outputter, error) {
+	factory, ok := outputterRegistry[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+	return factory(p), nil
+}
+
+// encodeIfNeeded trims spaces and optionally base64-encodes a secret value.
+func encodeIfNeeded(value string, encode bool) string {
+	value = strings.Trim(value, " ")
+	if encode {
+		value = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return value
+}
+
+// Write saves the secrets to a YAML file.
+func (o *yamlOutputter) Write(path string, section string, secrets []Secret) error {
+	logrus.WithField("output_path", path).Infoln("Writing secrets to the file.")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		logrus.WithError(err).Errorln("Failed writing secrets to to the file.")
+		return err
+	}
+	defer f.Close()
+
+	f.WriteString(fmt.Sprintf("---\n%s:\n", section))
+	for _, secret := range secrets {
+		key := strings.Trim(secret.Key, " ")
+		value := encodeIfNeeded(secret.Value, o.encode)
+
+		logrus.WithField("key", key).WithField("value", "(hidden)").Infoln("Secret saved.")
+		f.WriteString(fmt.Sprintf("  %s: '%s'\n", key, value))
+	}
+
+	logrus.WithField("outputPath", path).WithField("count", len(secrets)).Infoln("Secrets successfully saved to the file.")
+	return nil
+}
+
+// Write saves the secrets to a JSON file, nested under section.
+func (o *jsonOutputter) Write(path string, section string, secrets []Secret) error {
+	logrus.WithField("output_path", path).Infoln("Writing secrets to the file.")
+
+	values := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		key := strings.Trim(secret.Key, " ")
+		values[key] = encodeIfNeeded(secret.Value, o.encode)
+		logrus.WithField("key", key).WithField("value", "(hidden)").Infoln("Secret saved.")
+	}
+
+	body, err := json.MarshalIndent(map[string]map[string]string{section: values}, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Errorln("Failed marshalling secrets to JSON.")
+		return err
+	}
+
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		logrus.WithError(err).Errorln("Failed writing secrets to the file.")
+		return err
+	}
+
+	logrus.WithField("outputPath", path).WithField("count", len(secrets)).Infoln("Secrets successfully saved to the file.")
+	return nil
+}
+
+// Write saves the secrets as KEY=VALUE pairs, e.g. for docker-compose --env-file.
+func (o *envOutputter) Write(path string, section string, secrets []Secret) error {
+	logrus.WithField("output_path", path).Infoln("Writing secrets to the file.")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		logrus.WithError(err).Errorln("Failed writing secrets to to the file.")
+		return err
+	}
+	defer f.Close()
+
+	f.WriteString(fmt.Sprintf("# %s\n", section))
+	for _, secret := range secrets {
+		key := strings.Trim(secret.Key, " ")
+		value := encodeIfNeeded(secret.Value, o.encode)
+
+		logrus.WithField("key", key).WithField("value", "(hidden)").Infoln("Secret saved.")
+		f.WriteString(fmt.Sprintf("%s=%s\n", key, value))
+	}
+
+	logrus.WithField("outputPath", path).WithField("count", len(secrets)).Infoln("Secrets successfully saved to the file.")
+	return nil
+}
+
+// Write saves the secrets as an HCL map variable, e.g. for Terraform tfvars.
+func (o *hclOutputter) Write(path string, section string, secrets []Secret) error {
+	logrus.WithField("output_path", path).Infoln("Writing secrets to the file.")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		logrus.WithError(err).Errorln("Failed writing secrets to to the file.")
+		return err
+	}
+	defer f.Close()
+
+	f.WriteString(fmt.Sprintf("%s = {\n", section))
+	for _, secret := range secrets {
+		key := strings.Trim(secret.Key, " ")
+		value := encodeIfNeeded(secret.Value, o.encode)
+
+		logrus.WithField("key", key).WithField("value", "(hidden)").Infoln("Secret saved.")
+		f.WriteString(fmt.Sprintf("  %s = %q\n", key, value))
+	}
+	f.WriteString("}\n")
+
+	logrus.WithField("outputPath", path).WithField("count", len(secrets)).Infoln("Secrets successfully saved to the file.")
+	return nil
+}
+
+// Write renders the secrets through a user-supplied Go template.
+func (o *templateOutputter) Write(path string, section string, secrets []Secret) error {
+	if o.templatePath == "" {
+		logrus.Errorln("Template output format selected but Config.Template is empty.")
+		return fmt.Errorf("template path is required for the TEMPLATE output format")
+	}
+
+	logrus.WithField("template", o.templatePath).WithField("output_path", path).Infoln("Rendering secrets through the template.")
+	tmpl, err := template.New(strings.TrimSuffix(strings.ToLower(section), " ")).ParseFiles(o.templatePath)
+	if err != nil {
+		logrus.WithError(err).WithField("template", o.templatePath).Errorln("Failed parsing the template file.")
+		return err
+	}
+
+	data := struct {
+		Section string
+		Secrets []templateSecret
+	}{
+		Section: section,
+	}
+	for _, secret := range secrets {
+		data.Secrets = append(data.Secrets, templateSecret{
+			Key:   strings.Trim(secret.Key, " "),
+			Value: encodeIfNeeded(secret.Value, o.encode),
+		})
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		logrus.WithError(err).Errorln("Failed writing secrets to to the file.")
+		return err
+	}
+	defer f.Close()
+
+	if err := tmpl.ExecuteTemplate(f, filepath.Base(o.templatePath), data); err != nil {
+		logrus.WithError(err).Errorln("Failed executing the template.")
+		return err
+	}
+
+	logrus.WithField("outputPath", path).WithField("count", len(secrets)).Infoln("Secrets successfully saved to the file.")
+	return nil
+}
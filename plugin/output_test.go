@@ -0,0 +1,142 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestYamlOutputterWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.yaml")
+
+	o := &yamlOutputter{encode: false}
+	if err := o.Write(path, "secrets", []Secret{{Key: "username", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if !strings.Contains(string(content), "secrets:\n") || !strings.Contains(string(content), "username: 'admin'") {
+		t.Fatalf("expected rendered secret, got:\n%s", content)
+	}
+}
+
+func TestYamlOutputterWriteEncoded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.yaml")
+
+	o := &yamlOutputter{encode: true}
+	if err := o.Write(path, "secrets", []Secret{{Key: "username", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "username: 'YWRtaW4='") {
+		t.Fatalf("expected base64-encoded value, got:\n%s", content)
+	}
+}
+
+func TestJsonOutputterWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+
+	o := &jsonOutputter{}
+	if err := o.Write(path, "secrets", []Secret{{Key: "username", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	var parsed map[string]map[string]string
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+	if parsed["secrets"]["username"] != "admin" {
+		t.Fatalf("expected secrets.username=admin, got %v", parsed)
+	}
+}
+
+func TestEnvOutputterWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.env")
+
+	o := &envOutputter{}
+	if err := o.Write(path, "secrets", []Secret{{Key: "USERNAME", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "USERNAME=admin") {
+		t.Fatalf("expected USERNAME=admin, got:\n%s", content)
+	}
+}
+
+func TestHclOutputterWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.tfvars")
+
+	o := &hclOutputter{}
+	if err := o.Write(path, "secrets", []Secret{{Key: "username", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	want := "secrets = {\n  username = \"admin\"\n}\n"
+	if string(content) != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, content)
+	}
+}
+
+func TestTemplateOutputterWrite(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "secrets.tmpl")
+	if err := os.WriteFile(templatePath, []byte("{{range .Secrets}}{{.Key}}={{.Value}}\n{{end}}"), 0644); err != nil {
+		t.Fatalf("failed to write the template file: %v", err)
+	}
+
+	outPath := filepath.Join(dir, "out.txt")
+	o := &templateOutputter{templatePath: templatePath}
+	if err := o.Write(outPath, "secrets", []Secret{{Key: "username", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(outPath)
+	if string(content) != "username=admin\n" {
+		t.Fatalf("expected rendered template, got:\n%s", content)
+	}
+}
+
+func TestTemplateOutputterWriteEncoded(t *testing.T) {
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "secrets.tmpl")
+	if err := os.WriteFile(templatePath, []byte("{{range .Secrets}}{{.Key}}={{.Value}}\n{{end}}"), 0644); err != nil {
+		t.Fatalf("failed to write the template file: %v", err)
+	}
+
+	outPath := filepath.Join(dir, "out.txt")
+	o := &templateOutputter{templatePath: templatePath, encode: true}
+	if err := o.Write(outPath, "secrets", []Secret{{Key: "username", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(outPath)
+	if string(content) != "username=YWRtaW4=\n" {
+		t.Fatalf("expected base64-encoded value, got:\n%s", content)
+	}
+}
+
+func TestTemplateOutputterWriteRequiresPath(t *testing.T) {
+	o := &templateOutputter{}
+	if err := o.Write(filepath.Join(t.TempDir(), "out.txt"), "secrets", nil); err == nil {
+		t.Fatal("expected an error when Template is not set")
+	}
+}
+
+func TestNewOutputterUnsupportedFormat(t *testing.T) {
+	p := &Plugin{Config: Config{OutputFormat: "XML"}}
+	if _, err := newOutputter(p, p.Config.OutputFormat); err == nil {
+		t.Fatal("expected an error for an unsupported output format")
+	}
+}
@@ -1,38 +1,57 @@
 package plugin
 
 import (
-	"crypto/tls"
-	"encoding/base64"
 	"errors"
-	"fmt"
-	"github.com/go-resty/resty"
 	"github.com/mattn/go-colorable"
 	"github.com/sirupsen/logrus"
 	"net/url"
-	"os"
-	"reflect"
-	"strconv"
 	"strings"
-	"time"
 )
 
 type (
 	// Plugin configuration
 	Config struct {
-		ApiEndpoint       string
-		ApiKey            string
-		PasswordListId    int
-		ConnectionRetries int
-		ConnectionTimeout int
-		SkipTlsVerify     bool
-		KeyField          string
-		ValueField        string
-		EncodeSecrets     bool
-		OutputPath        string
-		OutputFormat      string
-		SectionName       string
-		Debug             bool
-		NoSecretsFail     bool
+		ApiEndpoint        string
+		ApiKey             string
+		PasswordListIds    []int
+		PasswordIds        []int
+		OnDuplicate        string
+		ConnectionRetries  int
+		ConnectionTimeout  int
+		RetryMaxWait       int
+		RetryBackoffJitter float64
+		SkipTlsVerify      bool
+		ClientCertFile     string
+		ClientKeyFile      string
+		CaCertFile         string
+		KeyField           string
+		ValueField         string
+		KeyRegex           string
+		KeyReplaceRegex    string
+		KeyReplaceWith     string
+		KeyPrefix          string
+		KeyCase            string
+		KeyTemplate        string
+		ValueRegex         string
+		ValueReplaceRegex  string
+		ValueReplaceWith   string
+		ValuePrefix        string
+		ValueCase          string
+		ValueTemplate      string
+		EncodeSecrets      bool
+		OutputPath         string
+		OutputFormat       string
+		Template           string
+		SectionName        string
+		Debug              bool
+		LogFormat          string
+		LogLevel           string
+		NoSecretsFail      bool
+		Sinks              []string
+		VaultAddr          string
+		VaultToken         string
+		VaultPath          string
+		VaultNamespace     string
 	}
 	// Plugin parameters
 	Plugin struct {
@@ -49,13 +68,26 @@ type (
 func (p *Plugin) Exec() error {
 
 	// Initiate the logging
-	logrus.SetFormatter(&logrus.TextFormatter{ForceColors: true, FullTimestamp: true})
+	if strings.ToLower(p.Config.LogFormat) == "json" {
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	} else {
+		logrus.SetFormatter(&logrus.TextFormatter{ForceColors: true, FullTimestamp: true})
+	}
 	logrus.SetOutput(colorable.NewColorableStdout())
+
+	level := logrus.InfoLevel
 	if p.Config.Debug {
-		logrus.SetLevel(logrus.DebugLevel)
-	} else {
-		logrus.SetLevel(logrus.InfoLevel)
+		level = logrus.DebugLevel
+	}
+	if p.Config.LogLevel != "" {
+		parsed, err := logrus.ParseLevel(p.Config.LogLevel)
+		if err != nil {
+			logrus.WithError(err).WithField("log_level", p.Config.LogLevel).Errorln("Provided log level is not valid.")
+			return err
+		}
+		level = parsed
 	}
+	logrus.SetLevel(level)
 	logrus.Infoln("Starting the execution.")
 
 	// Validate the parameters:
@@ -64,17 +96,18 @@ func (p *Plugin) Exec() error {
 		logrus.WithError(err).WithField("endpoint", p.Config.ApiEndpoint).Errorln("Provided API endpoint is not valid.")
 		return err
 	}
-	if p.Config.PasswordListId == 0 {
-		logrus.WithField("list_id", p.Config.PasswordListId).Errorln("Provided list ID is not valid.")
-		return errors.New("provided list ID is not valid")
+	if len(p.Config.PasswordListIds) == 0 && len(p.Config.PasswordIds) == 0 {
+		logrus.Errorln("At least one of PasswordListIds or PasswordIds must be provided.")
+		return errors.New("at least one of PasswordListIds or PasswordIds must be provided")
 	}
 	if p.Config.ApiKey == "" {
 		logrus.Errorln("API key is mandatory.")
 		return errors.New("api key is mandatory")
 	}
-	if p.Config.OutputFormat != "YAML" {
-		logrus.Errorln("Currently only YAML format is supported.")
-		return errors.New("currently only YAML format is supported")
+	sinks, err := newSinks(p)
+	if err != nil {
+		logrus.WithError(err).WithField("sinks", p.Config.Sinks).Errorln("Provided sinks are not supported.")
+		return err
 	}
 
 	// Retrieve the secrets from PasswordState:
@@ -88,134 +121,13 @@ func (p *Plugin) Exec() error {
 		return errors.New("no secrets were retrieved from PasswordState")
 	}
 
-	// Save the secrets to file:
-	if p.Config.OutputFormat == "YAML" {
-		outputToYaml(p.Config.OutputPath, p.Config.SectionName, p.Config.EncodeSecrets, secrets)
+	// Save the secrets to their destinations:
+	for _, sink := range sinks {
+		if err := sink.Send(secrets); err != nil {
+			return err
+		}
 	}
 
 	logrus.Infoln("Finished the execution.")
 	return nil
 }
-
-// Saves the secrets to YAML file
-func outputToYaml(filename string, section string, encode bool, secrets []Secret) error {
-	logrus.WithField("output_path", filename).Infoln("Writing secrets to the file.")
-	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0644)
-	defer f.Close()
-	if err != nil {
-		logrus.WithError(err).Errorln("Failed writing secrets to to the file.")
-		return err
-	}
-	f.WriteString(fmt.Sprintf("---\n%s:\n", string(section)))
-	for _, secret := range secrets {
-		// Trim spaces and encode the secrets if needed:
-        key := strings.Trim(secret.Key, " ")
-        value := strings.Trim(secret.Value, " ")
-		if encode {
-			value = base64.StdEncoding.EncodeToString([]byte(value))
-        }
-
-		logrus.WithField("key", key).WithField("value", "(hidden)").Infoln("Secret saved.")
-		f.WriteString(fmt.Sprintf("  %s: '%s'\n", key, value))
-	}
-
-	logrus.WithField("outputPath", filename).WithField("count", len(secrets)).Infoln("Secrets successfully saved to the file.")
-	return nil
-}
-
-// Retrieves the secrets from PasswordState
-func getSecrets(p *Plugin) ([]Secret, error) {
-	type (
-		// PasswordState JSON response for the passwords
-		PasswordList struct {
-			PasswordID     int    `json:"PasswordID"`
-			Title          string `json:"Title"`
-			UserName       string `json:"UserName"`
-			Description    string `json:"Description"`
-			GenericField1  string `json:"GenericField1"`
-			GenericField2  string `json:"GenericField2"`
-			GenericField3  string `json:"GenericField3"`
-			GenericField4  string `json:"GenericField4"`
-			GenericField5  string `json:"GenericField5"`
-			GenericField6  string `json:"GenericField6"`
-			GenericField7  string `json:"GenericField7"`
-			GenericField8  string `json:"GenericField8"`
-			GenericField9  string `json:"GenericField9"`
-			GenericField10 string `json:"GenericField10"`
-			AccountTypeID  int    `json:"AccountTypeID"`
-			Notes          string `json:"Notes"`
-			URL            string `json:"URL"`
-			Password       string `json:"Password"`
-			ExpiryDate     string `json:"ExpiryDate"`
-			AllowExport    bool   `json:"AllowExport"`
-			AccountType    string `json:"AccountType"`
-		}
-	)
-
-	var (
-		url     strings.Builder
-		secrets []Secret
-	)
-
-	url.WriteString(strings.TrimRight(p.Config.ApiEndpoint, "/"))
-	url.WriteString("/passwords/{PasswordListID}")
-
-	// Configure the API client:
-	client := resty.New()
-	client.
-		SetRetryCount(p.Config.ConnectionRetries).
-		SetTimeout(time.Duration(p.Config.ConnectionTimeout) * time.Second)
-	if p.Config.Debug {
-		client.SetDebug(true)
-	}
-	if p.Config.SkipTlsVerify {
-		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: p.Config.SkipTlsVerify})
-	}
-	client.
-		SetQueryParams(map[string]string{
-			"QueryAll":        "true",
-			"PreventAuditing": "false",
-		}).
-		SetPathParams(map[string]string{
-			"PasswordListID": strconv.Itoa(p.Config.PasswordListId),
-		}).
-		SetHeaders(map[string]string{
-			"APIKey":       p.Config.ApiKey,
-			"Content-Type": "application/json",
-		})
-
-	// Send the request:
-	logrus.WithField("endpoint", p.Config.ApiEndpoint).WithField("list_id", p.Config.PasswordListId).Infoln("Querying PasswordState API.")
-	response, err := client.R().
-		SetResult([]PasswordList{}).
-		Get(url.String())
-
-	if err != nil {
-		logrus.WithError(err).Errorln("Failed to retrieved data from PasswordState.")
-		return nil, err
-	}
-
-	passwords := *response.Result().(*[]PasswordList)
-	logrus.WithField("count", len(passwords)).Infoln("Passwords retrieved from PasswordState.")
-	logrus.WithField("key_field", p.Config.KeyField).WithField("value_field", p.Config.ValueField).Infoln("Converting retrieved passwords to secrets.")
-	for _, password := range passwords {
-		key := reflect.Indirect(reflect.ValueOf(password)).FieldByName(p.Config.KeyField).String()
-		if key == "" || key == "<invalid Value>" {
-			logrus.WithField("password_id", password.PasswordID).WithField("field", p.Config.KeyField).Warnln("Key is empty. Skipping the secret.")
-			continue
-		}
-		value := reflect.Indirect(reflect.ValueOf(password)).FieldByName(p.Config.ValueField).String()
-		if value == "" || value == "<invalid Value>" {
-			logrus.WithField("password_id", password.PasswordID).WithField("field", p.Config.ValueField).Warnln("Value is empty. Skipping the secret.")
-			continue
-		}
-		secret := Secret{
-			Key:   key,
-			Value: value,
-		}
-		secrets = append(secrets, secret)
-	}
-
-	logrus.WithField("count", len(secrets)).Infoln("Finished processing the secrets.")
-	return secrets, nil
-}
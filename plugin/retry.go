@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRetryWait is the base wait time fed to resty's own capped
+// exponential backoff (SetRetryWaitTime/SetRetryMaxWaitTime already jitter
+// internally, so no custom backoff math is needed here).
+const defaultRetryWait = time.Second
+
+// sleep is a var so tests can stub out the actual wait.
+var sleep = time.Sleep
+
+// attemptCounter tracks how many times each request URL has been attempted,
+// since this resty version exposes no per-request attempt count of its own.
+type attemptCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newAttemptCounter() *attemptCounter {
+	return &attemptCounter{counts: make(map[string]int)}
+}
+
+func (c *attemptCounter) next(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[key]++
+	return c.counts[key]
+}
+
+// shouldRetry retries on 5xx and 429 responses, since PasswordState returns
+// those under load (e.g. during backups). 401/403 are terminal - retrying an
+// auth failure cannot succeed.
+func shouldRetry(attempts *attemptCounter) func(*resty.Response) (bool, error) {
+	return func(r *resty.Response) (bool, error) {
+		if r == nil {
+			return false, nil
+		}
+
+		status := r.StatusCode()
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			return false, nil
+		}
+
+		retry := status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+		if retry {
+			attempt := attempts.next(r.Request.URL)
+			logrus.WithField("status", status).WithField("attempt", attempt).Warnln("Retrying request to PasswordState.")
+		}
+		return retry, nil
+	}
+}
+
+// retryAfterTransport wraps an http.RoundTripper and, for a 429/5xx response
+// carrying a Retry-After header, blocks for the duration it specifies before
+// handing the response back. This resty version has no SetRetryAfter hook,
+// so honoring the header has to happen at the transport level instead.
+type retryAfterTransport struct {
+	next http.RoundTripper
+	// jitter is the fraction of the Retry-After wait that may be added on
+	// top of it, e.g. 0.1 adds up to 10% extra wait, spreading out clients
+	// that all got the same Retry-After value. Zero disables jitter.
+	jitter float64
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+		return resp, nil
+	}
+
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		wait = applyJitter(wait, t.jitter)
+		logrus.WithField("wait", wait).WithField("status", resp.StatusCode).Infoln("Honoring Retry-After header from PasswordState.")
+		sleep(wait)
+	}
+	return resp, nil
+}
+
+// applyJitter adds a randomized extra wait of up to jitter times wait (a
+// fraction, e.g. 0.5 adds up to 50%) on top of wait. A non-positive jitter or
+// wait is returned unchanged.
+func applyJitter(wait time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || wait <= 0 {
+		return wait
+	}
+	return wait + time.Duration(rand.Float64()*jitter*float64(wait))
+}
+
+// parseRetryAfter understands both forms of the Retry-After header: a
+// number of seconds, or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
@@ -0,0 +1,183 @@
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty"
+)
+
+func TestShouldRetry(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		want   bool
+	}{
+		{"ok", http.StatusOK, false},
+		{"too many requests", http.StatusTooManyRequests, true},
+		{"internal server error", http.StatusInternalServerError, true},
+		{"bad gateway", http.StatusBadGateway, true},
+		{"unauthorized is terminal", http.StatusUnauthorized, false},
+		{"forbidden is terminal", http.StatusForbidden, false},
+		{"not found", http.StatusNotFound, false},
+	}
+
+	retry := shouldRetry(newAttemptCounter())
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := &resty.Response{RawResponse: &http.Response{StatusCode: c.status}, Request: &resty.Request{URL: "http://example.com"}}
+			got, err := retry(resp)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Fatalf("shouldRetry(%d) = %v, want %v", c.status, got, c.want)
+			}
+		})
+	}
+}
+
+func TestShouldRetryNilResponse(t *testing.T) {
+	retry := shouldRetry(newAttemptCounter())
+	got, err := retry(nil)
+	if err != nil || got {
+		t.Fatalf("shouldRetry(nil) = (%v, %v), want (false, nil)", got, err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"empty", "", 0, false},
+		{"seconds", "5", 5 * time.Second, true},
+		{"garbage", "not-a-wait-value", 0, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(c.value)
+			if ok != c.wantOk {
+				t.Fatalf("parseRetryAfter(%q) ok = %v, want %v", c.value, ok, c.wantOk)
+			}
+			if ok && got != c.want {
+				t.Fatalf("parseRetryAfter(%q) = %v, want %v", c.value, got, c.want)
+			}
+		})
+	}
+
+	t.Run("http date", func(t *testing.T) {
+		when := time.Now().Add(10 * time.Second)
+		got, ok := parseRetryAfter(when.UTC().Format(http.TimeFormat))
+		if !ok {
+			t.Fatal("expected the HTTP date to parse")
+		}
+		if got < 8*time.Second || got > 10*time.Second {
+			t.Fatalf("parseRetryAfter(date) = %v, want roughly 10s", got)
+		}
+	})
+}
+
+func TestApplyJitter(t *testing.T) {
+	if got := applyJitter(5*time.Second, 0); got != 5*time.Second {
+		t.Fatalf("applyJitter with zero jitter = %v, want unchanged 5s", got)
+	}
+	if got := applyJitter(0, 0.5); got != 0 {
+		t.Fatalf("applyJitter with zero wait = %v, want 0", got)
+	}
+
+	got := applyJitter(10*time.Second, 0.5)
+	if got < 10*time.Second || got > 15*time.Second {
+		t.Fatalf("applyJitter(10s, 0.5) = %v, want between 10s and 15s", got)
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryAfterTransportHonorsHeader(t *testing.T) {
+	slept := time.Duration(-1)
+	sleep = func(d time.Duration) { slept = d }
+	defer func() { sleep = time.Sleep }()
+
+	transport := &retryAfterTransport{
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.Header().Set("Retry-After", "2")
+			resp.WriteHeader(http.StatusTooManyRequests)
+			return resp.Result(), nil
+		}),
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slept != 2*time.Second {
+		t.Fatalf("expected to sleep for the Retry-After duration, slept %v", slept)
+	}
+}
+
+func TestRetryAfterTransportIgnoresSuccess(t *testing.T) {
+	slept := time.Duration(-1)
+	sleep = func(d time.Duration) { slept = d }
+	defer func() { sleep = time.Sleep }()
+
+	transport := &retryAfterTransport{
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := httptest.NewRecorder()
+			resp.WriteHeader(http.StatusOK)
+			return resp.Result(), nil
+		}),
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slept != -1 {
+		t.Fatalf("expected no sleep for a successful response, slept %v", slept)
+	}
+}
+
+func TestFetchPasswordListReturnsErrorOnFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	p := &Plugin{Config: Config{ApiEndpoint: server.URL, ApiKey: "key"}}
+	client, err := newPasswordStateClient(p)
+	if err != nil {
+		t.Fatalf("unexpected error building the client: %v", err)
+	}
+
+	if _, err := fetchPasswordList(client, p, 1); err == nil {
+		t.Fatal("expected an error for a 500 response, got none")
+	}
+}
+
+func TestFetchPasswordReturnsErrorOnFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	p := &Plugin{Config: Config{ApiEndpoint: server.URL, ApiKey: "key"}}
+	client, err := newPasswordStateClient(p)
+	if err != nil {
+		t.Fatalf("unexpected error building the client: %v", err)
+	}
+
+	if _, err := fetchPassword(client, p, 1); err == nil {
+		t.Fatal("expected an error for a 401 response, got none")
+	}
+}
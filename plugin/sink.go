@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Sink delivers the retrieved secrets to a destination (a file, Vault, ...).
+	// A single plugin execution can fan out to several sinks.
+	Sink interface {
+		Send(secrets []Secret) error
+	}
+
+	// fileSink adapts an Outputter (which writes to a path) to the Sink interface.
+	fileSink struct {
+		outputter Outputter
+		path      string
+		section   string
+	}
+)
+
+// sinkRegistry maps a Config.Sinks entry to the constructor of its Sink.
+var sinkRegistry = map[string]func(p *Plugin) (Sink, error){
+	"file": func(p *Plugin) (Sink, error) {
+		outputter, err := newOutputter(p, p.Config.OutputFormat)
+		if err != nil {
+			return nil, err
+		}
+		return &fileSink{outputter: outputter, path: p.Config.OutputPath, section: p.Config.SectionName}, nil
+	},
+	"vault": func(p *Plugin) (Sink, error) {
+		return newVaultSink(p), nil
+	},
+}
+
+// newSinks resolves the Sinks configured on the plugin, defaulting to the file
+// sink alone so existing configurations keep behaving as before.
+func newSinks(p *Plugin) ([]Sink, error) {
+	names := p.Config.Sinks
+	if len(names) == 0 {
+		names = []string{"file"}
+	}
+
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		factory, ok := sinkRegistry[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported sink: %s", name)
+		}
+		sink, err := factory(p)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// Send writes the secrets through the wrapped Outputter.
+func (s *fileSink) Send(secrets []Secret) error {
+	return s.outputter.Write(s.path, s.section, secrets)
+}
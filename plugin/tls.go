@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	errClientCertIncomplete = errors.New("both ClientCertFile and ClientKeyFile must be set to use mTLS")
+	errCaCertInvalid        = errors.New("ca certificate file does not contain a valid PEM certificate")
+)
+
+// buildTLSConfig assembles the TLS configuration used to talk to the
+// PasswordState API, layering client-certificate (mTLS) and CA pinning
+// support on top of the existing SkipTlsVerify switch.
+func buildTLSConfig(p *Plugin) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: p.Config.SkipTlsVerify}
+
+	if p.Config.ClientCertFile != "" || p.Config.ClientKeyFile != "" {
+		if p.Config.ClientCertFile == "" || p.Config.ClientKeyFile == "" {
+			logrus.Errorln("Both ClientCertFile and ClientKeyFile must be set to use mTLS.")
+			return nil, errClientCertIncomplete
+		}
+		cert, err := tls.LoadX509KeyPair(p.Config.ClientCertFile, p.Config.ClientKeyFile)
+		if err != nil {
+			logrus.WithError(err).Errorln("Failed to load the client certificate/key pair.")
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if p.Config.CaCertFile != "" {
+		caCert, err := os.ReadFile(p.Config.CaCertFile)
+		if err != nil {
+			logrus.WithError(err).Errorln("Failed to read the CA certificate file.")
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			logrus.Errorln("Failed to parse the CA certificate file.")
+			return nil, errCaCertInvalid
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
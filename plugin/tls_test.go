@@ -0,0 +1,171 @@
+package plugin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// generateCertificate creates a self-signed PEM-encoded certificate/key pair,
+// optionally signed by a given CA, for use as test fixtures.
+func generateCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (certPEM, keyPEM []byte, cert *x509.Certificate, key *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  ca == nil,
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer := template
+	signerKey := key
+	if ca != nil {
+		signer = ca
+		signerKey = caKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey})
+
+	return certPEM, keyPEM, cert, key
+}
+
+func writeTempFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestBuildTLSConfigWithClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+
+	caCertPEM, _, caCert, caKey := generateCertificate(t, nil, nil)
+	clientCertPEM, clientKeyPEM, _, _ := generateCertificate(t, caCert, caKey)
+	serverCertPEM, serverKeyPEM, _, _ := generateCertificate(t, caCert, caKey)
+
+	caCertFile := writeTempFile(t, dir, "ca.pem", caCertPEM)
+	clientCertFile := writeTempFile(t, dir, "client.pem", clientCertPEM)
+	clientKeyFile := writeTempFile(t, dir, "client-key.pem", clientKeyPEM)
+
+	serverCert, err := tls.X509KeyPair(serverCertPEM, serverKeyPEM)
+	if err != nil {
+		t.Fatalf("failed to build the server certificate: %v", err)
+	}
+
+	clientCertPool := x509.NewCertPool()
+	clientCertPool.AppendCertsFromPEM(caCertPEM)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "ok")
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCertPool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	p := &Plugin{Config: Config{
+		ClientCertFile: clientCertFile,
+		ClientKeyFile:  clientKeyFile,
+		CaCertFile:     caCertFile,
+	}}
+
+	tlsConfig, err := buildTLSConfig(p)
+	if err != nil {
+		t.Fatalf("buildTLSConfig returned an error: %v", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with mTLS client failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestBuildTLSConfigWithoutClientCertificateRejected(t *testing.T) {
+	caCertPEM, _, _, _ := generateCertificate(t, nil, nil)
+
+	clientCertPool := x509.NewCertPool()
+	clientCertPool.AppendCertsFromPEM(caCertPEM)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCertPool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	p := &Plugin{Config: Config{SkipTlsVerify: true}}
+	tlsConfig, err := buildTLSConfig(p)
+	if err != nil {
+		t.Fatalf("buildTLSConfig returned an error: %v", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	_, err = client.Get(server.URL)
+	if err == nil {
+		t.Fatal("expected the request without a client certificate to fail")
+	}
+}
+
+func TestBuildTLSConfigRejectsIncompleteClientCertPair(t *testing.T) {
+	p := &Plugin{Config: Config{ClientCertFile: "only-cert.pem"}}
+
+	_, err := buildTLSConfig(p)
+	if err != errClientCertIncomplete {
+		t.Fatalf("expected errClientCertIncomplete, got %v", err)
+	}
+}
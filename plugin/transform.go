@@ -0,0 +1,156 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// Transformer is one step in a key/value transform pipeline. It returns the
+// transformed value and whether it should be kept (false drops the secret
+// entirely, e.g. a regex filter that didn't match).
+type Transformer func(password PasswordList, value string) (string, bool, error)
+
+// keyTransformers builds the configured key pipeline, applied in this order:
+// regex filter, template override, regex replace, prefix, case normalization.
+func keyTransformers(p *Plugin) []Transformer {
+	return []Transformer{
+		regexFilterTransformer(p.Config.KeyRegex),
+		templateTransformer("key", p.Config.KeyTemplate),
+		replaceTransformer(p.Config.KeyReplaceRegex, p.Config.KeyReplaceWith),
+		prefixTransformer(p.Config.KeyPrefix),
+		caseTransformer(p.Config.KeyCase),
+	}
+}
+
+// valueTransformers mirrors keyTransformers for the value side.
+func valueTransformers(p *Plugin) []Transformer {
+	return []Transformer{
+		regexFilterTransformer(p.Config.ValueRegex),
+		templateTransformer("value", p.Config.ValueTemplate),
+		replaceTransformer(p.Config.ValueReplaceRegex, p.Config.ValueReplaceWith),
+		prefixTransformer(p.Config.ValuePrefix),
+		caseTransformer(p.Config.ValueCase),
+	}
+}
+
+// runTransformers feeds value through each Transformer in turn, short-circuiting
+// on the first error or dropped value.
+func runTransformers(transformers []Transformer, password PasswordList, value string) (string, bool, error) {
+	for _, transform := range transformers {
+		next, keep, err := transform(password, value)
+		if err != nil {
+			return "", false, err
+		}
+		if !keep {
+			return "", false, nil
+		}
+		value = next
+	}
+	return value, true, nil
+}
+
+// applyKeyTransforms runs the configured key transform pipeline against key.
+func applyKeyTransforms(p *Plugin, password PasswordList, key string) (string, bool, error) {
+	return runTransformers(keyTransformers(p), password, key)
+}
+
+// applyValueTransforms runs the configured value transform pipeline against value.
+func applyValueTransforms(p *Plugin, password PasswordList, value string) (string, bool, error) {
+	return runTransformers(valueTransformers(p), password, value)
+}
+
+// regexFilterTransformer drops values that don't match pattern. An empty
+// pattern is a no-op.
+func regexFilterTransformer(pattern string) Transformer {
+	return func(password PasswordList, value string) (string, bool, error) {
+		if pattern == "" {
+			return value, true, nil
+		}
+		matched, err := regexp.MatchString(pattern, value)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		return value, matched, nil
+	}
+}
+
+// templateTransformer overrides value with a Go template rendered against
+// .Password. An empty template text is a no-op.
+func templateTransformer(name string, text string) Transformer {
+	return func(password PasswordList, value string) (string, bool, error) {
+		if text == "" {
+			return value, true, nil
+		}
+		rendered, err := renderTemplate(name, text, password)
+		if err != nil {
+			return "", false, err
+		}
+		return rendered, true, nil
+	}
+}
+
+// replaceTransformer rewrites value by replacing every match of pattern with
+// replacement. An empty pattern is a no-op.
+func replaceTransformer(pattern string, replacement string) Transformer {
+	return func(password PasswordList, value string) (string, bool, error) {
+		if pattern == "" {
+			return value, true, nil
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		return re.ReplaceAllString(value, replacement), true, nil
+	}
+}
+
+// prefixTransformer prepends prefix to value. An empty prefix is a no-op.
+func prefixTransformer(prefix string) Transformer {
+	return func(password PasswordList, value string) (string, bool, error) {
+		return prefix + value, true, nil
+	}
+}
+
+// caseTransformer normalizes value per applyCase.
+func caseTransformer(mode string) Transformer {
+	return func(password PasswordList, value string) (string, bool, error) {
+		return applyCase(value, mode), true, nil
+	}
+}
+
+// applyCase normalizes s per the given case mode: "upper", "lower", or
+// "screaming_snake" (upper-cased, non-alphanumeric runs collapsed to "_").
+// Any other value, including empty, leaves s untouched.
+func applyCase(s string, mode string) string {
+	switch strings.ToLower(mode) {
+	case "upper":
+		return strings.ToUpper(s)
+	case "lower":
+		return strings.ToLower(s)
+	case "screaming_snake":
+		s = nonAlphanumeric.ReplaceAllString(s, "_")
+		return strings.ToUpper(strings.Trim(s, "_"))
+	default:
+		return s
+	}
+}
+
+// renderTemplate executes a Go template with .Password bound to the
+// PasswordList the key/value were extracted from.
+func renderTemplate(name string, text string, password PasswordList) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Password PasswordList }{Password: password}); err != nil {
+		return "", fmt.Errorf("failed executing %s template: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,79 @@
+package plugin
+
+import "testing"
+
+func TestApplyKeyTransformsPipeline(t *testing.T) {
+	p := &Plugin{Config: Config{
+		KeyReplaceRegex: "^svc_",
+		KeyReplaceWith:  "",
+		KeyPrefix:       "app_",
+		KeyCase:         "upper",
+	}}
+
+	key, keep, err := applyKeyTransforms(p, PasswordList{}, "svc_database")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !keep {
+		t.Fatal("expected the key to be kept")
+	}
+	if key != "APP_DATABASE" {
+		t.Fatalf("expected APP_DATABASE, got %q", key)
+	}
+}
+
+func TestApplyKeyTransformsRegexFilter(t *testing.T) {
+	p := &Plugin{Config: Config{KeyRegex: "^prod_"}}
+
+	_, keep, err := applyKeyTransforms(p, PasswordList{}, "staging_database")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keep {
+		t.Fatal("expected the key to be filtered out")
+	}
+}
+
+func TestApplyKeyTransformsTemplate(t *testing.T) {
+	p := &Plugin{Config: Config{KeyTemplate: "{{.Password.Title}}_{{.Password.UserName}}"}}
+	password := PasswordList{Title: "Database", UserName: "admin"}
+
+	key, keep, err := applyKeyTransforms(p, password, "ignored")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !keep {
+		t.Fatal("expected the key to be kept")
+	}
+	if key != "Database_admin" {
+		t.Fatalf("expected Database_admin, got %q", key)
+	}
+}
+
+func TestApplyCaseScreamingSnake(t *testing.T) {
+	got := applyCase("Database Password-1", "screaming_snake")
+	want := "DATABASE_PASSWORD_1"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestApplyValueTransformsRegexFilter(t *testing.T) {
+	p := &Plugin{Config: Config{ValueRegex: "^[0-9]+$"}}
+
+	_, keep, err := applyValueTransforms(p, PasswordList{}, "not-numeric")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keep {
+		t.Fatal("expected the value to be filtered out")
+	}
+}
+
+func TestApplyKeyTransformsInvalidRegex(t *testing.T) {
+	p := &Plugin{Config: Config{KeyRegex: "("}}
+
+	if _, _, err := applyKeyTransforms(p, PasswordList{}, "key"); err == nil {
+		t.Fatal("expected an error for an invalid KeyRegex")
+	}
+}
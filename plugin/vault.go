@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+)
+
+// vaultSink pushes the retrieved secrets into a HashiCorp Vault KV v2 mount.
+type vaultSink struct {
+	addr      string
+	token     string
+	path      string
+	namespace string
+	section   string
+	encode    bool
+}
+
+// newVaultSink builds a vaultSink from the plugin configuration, falling back
+// to the VAULT_TOKEN environment variable when Config.VaultToken is unset.
+func newVaultSink(p *Plugin) *vaultSink {
+	token := p.Config.VaultToken
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	return &vaultSink{
+		addr:      p.Config.VaultAddr,
+		token:     token,
+		path:      p.Config.VaultPath,
+		namespace: p.Config.VaultNamespace,
+		section:   p.Config.SectionName,
+		encode:    p.Config.EncodeSecrets,
+	}
+}
+
+// Send writes the secrets to Vault as a single KV v2 entry under the configured path.
+func (s *vaultSink) Send(secrets []Secret) error {
+	if s.addr == "" || s.token == "" || s.path == "" {
+		logrus.Errorln("Vault sink is selected but VaultAddr, VaultToken, or VaultPath is not set.")
+		return fmt.Errorf("vault sink requires VaultAddr, VaultToken, and VaultPath to be set")
+	}
+
+	config := vault.DefaultConfig()
+	config.Address = s.addr
+	client, err := vault.NewClient(config)
+	if err != nil {
+		logrus.WithError(err).Errorln("Failed to create the Vault client.")
+		return err
+	}
+	client.SetToken(s.token)
+	if s.namespace != "" {
+		client.SetNamespace(s.namespace)
+	}
+
+	data := make(map[string]interface{}, len(secrets))
+	for _, secret := range secrets {
+		key := strings.Trim(secret.Key, " ")
+		data[key] = encodeIfNeeded(secret.Value, s.encode)
+		logrus.WithField("key", key).WithField("value", "(hidden)").Infoln("Secret staged for Vault.")
+	}
+
+	dataPath := vaultDataPath(s.path, s.section)
+	logrus.WithField("vault_path", dataPath).Infoln("Writing secrets to Vault.")
+	if _, err := client.Logical().Write(dataPath, map[string]interface{}{"data": data}); err != nil {
+		logrus.WithError(err).WithField("vault_path", dataPath).Errorln("Failed writing secrets to Vault.")
+		return err
+	}
+
+	logrus.WithField("vault_path", dataPath).WithField("count", len(secrets)).Infoln("Secrets successfully written to Vault.")
+	return nil
+}
+
+// vaultDataPath rewrites a "<mount>/<path>" KV v2 logical path into its
+// "<mount>/data/<path>" API form, appending section as a sub-path when set.
+func vaultDataPath(path string, section string) string {
+	path = strings.Trim(path, "/")
+	if section != "" {
+		path = path + "/" + strings.Trim(section, "/")
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) == 1 {
+		return parts[0] + "/data"
+	}
+	return parts[0] + "/data/" + parts[1]
+}
@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestVaultDataPath(t *testing.T) {
+	cases := []struct {
+		path, section, want string
+	}{
+		{"secret/myapp", "", "secret/data/myapp"},
+		{"secret", "", "secret/data"},
+		{"secret/myapp", "prod", "secret/data/myapp/prod"},
+		{"/secret/myapp/", "/prod/", "secret/data/myapp/prod"},
+	}
+
+	for _, c := range cases {
+		if got := vaultDataPath(c.path, c.section); got != c.want {
+			t.Errorf("vaultDataPath(%q, %q) = %q, want %q", c.path, c.section, got, c.want)
+		}
+	}
+}
+
+func TestNewVaultSinkTokenFallsBackToEnv(t *testing.T) {
+	os.Setenv("VAULT_TOKEN", "env-token")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	p := &Plugin{Config: Config{VaultAddr: "http://localhost:8200", VaultPath: "secret/app"}}
+	sink := newVaultSink(p)
+	if sink.token != "env-token" {
+		t.Fatalf("expected token from VAULT_TOKEN env var, got %q", sink.token)
+	}
+}
+
+func TestVaultSinkSendRequiresConfig(t *testing.T) {
+	sink := &vaultSink{}
+	if err := sink.Send([]Secret{{Key: "k", Value: "v"}}); err == nil {
+		t.Fatal("expected an error when VaultAddr, VaultToken, or VaultPath is not set")
+	}
+}
+
+func TestVaultSinkSendWritesToKVv2(t *testing.T) {
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Fatalf("expected a PUT request, got %s", r.Method)
+		}
+		if r.URL.Path != "/v1/secret/data/myapp" {
+			t.Fatalf("unexpected request path: %s", r.URL.Path)
+		}
+
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read the request body: %v", err)
+		}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Fatalf("failed to decode the request body: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	sink := &vaultSink{
+		addr:  server.URL,
+		token: "test-token",
+		path:  "secret/myapp",
+	}
+
+	if err := sink.Send([]Secret{{Key: "username", Value: "admin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body.Data["username"] != "admin" {
+		t.Fatalf("expected username=admin to be written to Vault, got %v", body.Data)
+	}
+}